@@ -0,0 +1,70 @@
+/*
+Copyright © 2024 Brian Ketelsen <bketelsen@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bketelsen/incus-compose/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+var lintOnly []string
+var lintFix bool
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Statically analyse the compose project for common mistakes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		findings := lint.Run(app, lintOnly)
+
+		if lintFix {
+			fmt.Println("--fix is not yet able to resolve any of the built-in rules automatically")
+		}
+
+		for _, f := range findings {
+			if f.Service != "" {
+				fmt.Printf("%s\t%s\t%s: %s\n", f.RuleID, f.Level, f.Service, f.Message)
+			} else {
+				fmt.Printf("%s\t%s\t%s\n", f.RuleID, f.Level, f.Message)
+			}
+		}
+
+		if len(findings) == 0 {
+			fmt.Println("no findings")
+			return nil
+		}
+
+		if strict || lint.HasErrors(findings) {
+			return fmt.Errorf("lint found %d finding(s)", len(findings))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringSliceVar(&lintOnly, "only", nil, "only run these rule IDs (e.g. R001,R014)")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "attempt to automatically fix findings that support it")
+
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2024 Brian Ketelsen <bketelsen@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bketelsen/incus-compose/pkg/recipe"
+	"github.com/spf13/cobra"
+)
+
+// recipeCmd is the parent for the catalogue subcommands.
+var recipeCmd = &cobra.Command{
+	Use:   "recipe",
+	Short: "Browse and manage the shared recipe catalogue",
+	Long:  `Browse and manage the shared catalogue of reusable, parameterised compose templates referenced via x-incus-recipe.`,
+}
+
+var recipeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the recipes available in the catalogue",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cat := recipe.NewCatalogue(conf.CacheDir, recipeCatalogueURL())
+
+		names, err := cat.List()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			fmt.Println(name)
+		}
+
+		return nil
+	},
+}
+
+var recipeNewCmd = &cobra.Command{
+	Use:   "new <recipe>[@version] <service-name>",
+	Short: "Scaffold a new project from a catalogue recipe",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := recipe.ParseRef(args[0])
+		if err != nil {
+			return err
+		}
+
+		cat := recipe.NewCatalogue(conf.CacheDir, recipeCatalogueURL())
+		rec, err := cat.Fetch(ref)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("services:\n  %s:\n    x-incus-recipe: %s\n", args[1], rec.Ref)
+		return nil
+	},
+}
+
+var recipeUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <recipe> <version>",
+	Short: "Check that a recipe version exists in the catalogue; the compose file is not edited",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref := recipe.Ref{Name: args[0], Version: args[1]}
+
+		cat := recipe.NewCatalogue(conf.CacheDir, recipeCatalogueURL())
+		if _, err := cat.Fetch(ref); err != nil {
+			return err
+		}
+
+		fmt.Printf("recipe %s version %s is available, update your compose file's x-incus-recipe value to match\n", ref.Name, ref.Version)
+		return nil
+	},
+}
+
+var recipeLintCmd = &cobra.Command{
+	Use:   "lint <recipe>[@version]",
+	Short: "Validate a recipe's manifest in isolation, outside of any project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := recipe.ParseRef(args[0])
+		if err != nil {
+			return err
+		}
+
+		cat := recipe.NewCatalogue(conf.CacheDir, recipeCatalogueURL())
+		rec, err := cat.Fetch(ref)
+		if err != nil {
+			return err
+		}
+
+		// There's no project here to check the recipe's declared networks
+		// against, so pass a nil networkTypes: recipe.Lint skips that check
+		// rather than flagging every declared network as undefined. usedEnv
+		// is nil for the same reason - this command has no service to scan.
+		results := recipe.Lint(rec, nil, nil)
+		if len(results) == 0 {
+			fmt.Printf("%s: ok\n", ref)
+			return nil
+		}
+
+		for _, r := range results {
+			fmt.Printf("%s: [%s] %s\n", ref, r.Level, r.Message)
+		}
+		return fmt.Errorf("recipe %s failed lint with %d finding(s)", ref, len(results))
+	},
+}
+
+// recipeCatalogueURL resolves the catalogue git remote, allowing the user to
+// point at a private fork via INCUS_COMPOSE_RECIPE_CATALOGUE.
+func recipeCatalogueURL() string {
+	if url := os.Getenv("INCUS_COMPOSE_RECIPE_CATALOGUE"); url != "" {
+		return url
+	}
+	return recipe.DefaultCatalogueURL
+}
+
+func init() {
+	rootCmd.AddCommand(recipeCmd)
+	recipeCmd.AddCommand(recipeListCmd)
+	recipeCmd.AddCommand(recipeNewCmd)
+	recipeCmd.AddCommand(recipeUpgradeCmd)
+	recipeCmd.AddCommand(recipeLintCmd)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2024 Brian Ketelsen <bketelsen@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bketelsen/incus-compose/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Inspect and replay recorded releases of this project",
+}
+
+var releaseLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the releases recorded for this project",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		releases, err := release.List(app.ProjectDir)
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range releases {
+			fmt.Printf("%s\t%s\n", rel.ShortSHA, time.UnixMilli(rel.Timestamp).Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var releaseShowCmd = &cobra.Command{
+	Use:   "show <sha>",
+	Short: "Show the merged compose YAML recorded for one release",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rel, err := release.Find(app.ProjectDir, args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(rel.Compose)
+		return nil
+	},
+}
+
+var releaseDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Diff two recorded releases",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := release.Find(app.ProjectDir, args[0])
+		if err != nil {
+			return err
+		}
+		b, err := release.Find(app.ProjectDir, args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(release.Diff(a, b))
+		return nil
+	},
+}
+
+var releaseRollbackCmd = &cobra.Command{
+	Use:   "rollback <sha>",
+	Short: "Replay a prior release's pinned network config",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return app.Rollback(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releaseLsCmd)
+	releaseCmd.AddCommand(releaseShowCmd)
+	releaseCmd.AddCommand(releaseDiffCmd)
+	releaseCmd.AddCommand(releaseRollbackCmd)
+}
@@ -27,15 +27,20 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"os/user"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/bketelsen/incus-compose/pkg/application"
 	"github.com/bketelsen/incus-compose/pkg/build"
 	"github.com/bketelsen/incus-compose/pkg/compose"
+	"github.com/bketelsen/incus-compose/pkg/lint"
+	"github.com/bketelsen/incus-compose/pkg/recipe"
 	"gopkg.in/yaml.v3"
 
 	dockercompose "github.com/compose-spec/compose-go/v2/types"
@@ -52,6 +57,7 @@ var debug bool
 var conf *config.Config
 var confPath string
 var forceLocal bool
+var strict bool
 
 // var app application.Compose
 var logLevel = new(slog.LevelVar) // Info by default
@@ -60,6 +66,12 @@ var cwd string
 var project *dockercompose.Project
 var app *application.Compose
 
+// rootCtx is cancelled on SIGINT/SIGTERM so `up`/`down` can abort
+// in-flight, parallel operations cleanly instead of leaving the stack
+// half-created.
+var rootCtx context.Context
+var rootCtxCancel context.CancelFunc
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use: "incus-compose",
@@ -146,6 +158,11 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+
+		if err := recipe.ResolveProject(project, recipe.NewCatalogue(conf.CacheDir, recipeCatalogueURL())); err != nil {
+			return err
+		}
+
 		app, err = application.BuildDirect(project, conf)
 		if err != nil {
 			return err
@@ -156,6 +173,17 @@ var rootCmd = &cobra.Command{
 
 		app.Remote = viper.GetString("remote")
 
+		app.Parallelism = viper.GetInt("parallel")
+		if app.Parallelism < 1 {
+			app.Parallelism = runtime.NumCPU()
+		}
+
+		app.NoPin = viper.GetBool("no-pin")
+
+		app.SecretsBackend = viper.GetString("secrets-backend")
+
+		rootCtx, rootCtxCancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 		g := graph.New(graph.StringHash, graph.Directed(), graph.Acyclic())
 		for name := range app.Services {
 			_ = g.AddVertex(name)
@@ -172,6 +200,20 @@ var rootCmd = &cobra.Command{
 			debugCompose()
 		}
 
+		if cmd.Name() != lintCmd.Name() {
+			findings := lint.Run(app, nil)
+			for _, f := range findings {
+				attrs := []any{slog.String("rule", f.RuleID)}
+				if f.Service != "" {
+					attrs = append(attrs, slog.String("service", f.Service))
+				}
+				slog.Warn(f.Message, attrs...)
+			}
+			if strict && len(findings) > 0 {
+				return fmt.Errorf("lint found %d finding(s) at --strict", len(findings))
+			}
+		}
+
 		return nil
 	},
 
@@ -187,6 +229,9 @@ var rootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	if rootCtxCancel != nil {
+		rootCtxCancel()
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -198,6 +243,9 @@ func init() {
 
 	rootCmd.PersistentFlags().Bool("dry-run", false, "print commands that would be executed without running them")
 
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "promote lint warnings to errors")
+	_ = viper.BindPFlag("strict", rootCmd.PersistentFlags().Lookup("strict"))
+
 	rootCmd.PersistentFlags().String("remote", "", "treat all images as docker images")
 	_ = viper.BindPFlag("remote", rootCmd.PersistentFlags().Lookup("remote"))
 	_ = viper.BindEnv("remote", "INCUS_COMPOSE_REMOTE")
@@ -213,6 +261,14 @@ func init() {
 	rootCmd.PersistentFlags().String("project", "", "use this incus project rather than the default one")
 	_ = viper.BindPFlag("incus-project", rootCmd.PersistentFlags().Lookup("project"))
 	_ = viper.BindEnv("incus-project", "INCUS_PROJECT")
+
+	rootCmd.PersistentFlags().Int("parallel", 0, "number of services/networks to operate on concurrently (default: number of CPUs)")
+	_ = viper.BindPFlag("parallel", rootCmd.PersistentFlags().Lookup("parallel"))
+	_ = viper.BindEnv("parallel", "INCUS_COMPOSE_PARALLEL")
+
+	rootCmd.PersistentFlags().Bool("no-pin", false, "recompute network config from the compose file instead of reusing the active release's pinned config")
+	_ = viper.BindPFlag("no-pin", rootCmd.PersistentFlags().Lookup("no-pin"))
+	_ = viper.BindEnv("no-pin", "INCUS_COMPOSE_NO_PIN")
 }
 
 func globalPreRunHook(_ *cobra.Command, _ []string) {
@@ -0,0 +1,94 @@
+/*
+Copyright © 2024 Brian Ketelsen <bketelsen@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bketelsen/incus-compose/pkg/secrets"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// secretCmd is the parent for the secret management subcommands.
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets declared via x-incus-secrets",
+}
+
+var secretLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the secrets declared across this project's services",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for name, service := range project.Services {
+			var specs []secrets.Spec
+			if ok, err := service.Extensions.Get("x-incus-secrets", &specs); !ok || err != nil {
+				continue
+			}
+			for _, s := range specs {
+				fmt.Printf("%s\t%s\t%s -> %s\n", name, s.Name, s.Source, s.Target)
+			}
+		}
+		return nil
+	},
+}
+
+var secretGenerateCmd = &cobra.Command{
+	Use:   "generate <path>",
+	Short: "Generate a random secret and write it to the configured backend",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rotateSecret(args[0])
+	},
+}
+
+var secretRotateCmd = &cobra.Command{
+	Use:   "rotate <path>",
+	Short: "Replace a secret's value and restart the services that depend on it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rotateSecret(args[0]); err != nil {
+			return err
+		}
+		return app.RestartDependents(args[0])
+	},
+}
+
+func rotateSecret(path string) error {
+	value, err := secrets.GenerateAndStore(viper.GetString("secrets-backend"), path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote new value for %s (%d bytes)\n", path, len(value))
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("secrets-backend", "pass", "backend used to resolve x-incus-secrets (pass, age, sops)")
+	_ = viper.BindPFlag("secrets-backend", rootCmd.PersistentFlags().Lookup("secrets-backend"))
+	_ = viper.BindEnv("secrets-backend", "INCUS_COMPOSE_SECRETS_BACKEND")
+
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretLsCmd)
+	secretCmd.AddCommand(secretGenerateCmd)
+	secretCmd.AddCommand(secretRotateCmd)
+}
@@ -1,95 +1,552 @@
 package application
 
 import (
+	"context"
 	"errors"
+	"strings"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+	"github.com/dominikbraun/graph"
+	incus "github.com/lxc/incus/v6/client"
 	api "github.com/lxc/incus/v6/shared/api"
 
 	"log/slog"
 )
 
-// CreateNetworks creates the default network for a stack
-func (c *Compose) CreateNetworks() error {
+// ovnACLRule is one entry of an ovnACL's ingress/egress list. It mirrors the
+// fields api.NetworkACLRule actually acts on, rather than a free-text
+// description, so "reject"/"drop" rules are never silently turned into an
+// allow.
+type ovnACLRule struct {
+	Action      string `yaml:"action"` // allow, reject or drop
+	Source      string `yaml:"source,omitempty"`
+	Destination string `yaml:"destination,omitempty"`
+	Protocol    string `yaml:"protocol,omitempty"`
+	Ports       string `yaml:"ports,omitempty"` // destination port(s)/range, e.g. "22" or "8000-9000"
+	Description string `yaml:"description,omitempty"`
+}
+
+// ovnACL is the shape of one entry under the x-incus-ovn-acls extension.
+type ovnACL struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description,omitempty"`
+	Ingress     []ovnACLRule `yaml:"ingress,omitempty"`
+	Egress      []ovnACLRule `yaml:"egress,omitempty"`
+}
+
+// ovnForwardPort is one entry of an ovnForward's ports list: where traffic
+// arriving on ListenPort is actually routed to.
+type ovnForwardPort struct {
+	ListenPort    string `yaml:"listen_port"`
+	Protocol      string `yaml:"protocol,omitempty"`
+	TargetAddress string `yaml:"target_address"`
+	TargetPort    string `yaml:"target_port,omitempty"`
+}
+
+// ovnForward is the shape of one entry under the x-incus-ovn-forwards extension.
+type ovnForward struct {
+	ListenAddress string           `yaml:"listen_address"`
+	Description   string           `yaml:"description,omitempty"`
+	Ports         []ovnForwardPort `yaml:"ports,omitempty"`
+}
+
+// ovnPeer is the shape of one entry under the x-incus-ovn-peers extension.
+type ovnPeer struct {
+	Name          string `yaml:"name"`
+	TargetProject string `yaml:"target_project,omitempty"`
+	TargetNetwork string `yaml:"target_network"`
+}
+
+// ovnLoadBalancerBackend is one entry of an ovnLoadBalancer's backends list,
+// named so a port can reference it via TargetBackend.
+type ovnLoadBalancerBackend struct {
+	Name          string `yaml:"name"`
+	TargetAddress string `yaml:"target_address"`
+	TargetPort    string `yaml:"target_port,omitempty"`
+}
+
+// ovnLoadBalancerPort is one entry of an ovnLoadBalancer's ports list,
+// routing a listen port to one or more named backends.
+type ovnLoadBalancerPort struct {
+	ListenPort    string   `yaml:"listen_port"`
+	Protocol      string   `yaml:"protocol,omitempty"`
+	TargetBackend []string `yaml:"target_backend"`
+}
+
+// ovnLoadBalancer is the shape of one entry under the
+// x-incus-ovn-load-balancers extension.
+type ovnLoadBalancer struct {
+	ListenAddress string                   `yaml:"listen_address"`
+	Backends      []ovnLoadBalancerBackend `yaml:"backends"`
+	Ports         []ovnLoadBalancerPort    `yaml:"ports,omitempty"`
+}
+
+// CreateNetworks creates every non-external network declared in the
+// project. Networks don't depend on one another, so they're created
+// concurrently, bounded by c.Parallelism; ctx cancellation (e.g. Ctrl-C)
+// stops any network not yet started.
+func (c *Compose) CreateNetworks(ctx context.Context) error {
 	slog.Info("Creating networks")
 
+	return c.forEachNetwork(ctx, c.createNetwork)
+}
+
+func (c *Compose) createNetwork(key string, network dockercompose.NetworkConfig) error {
+	var nettype string
+	var uplink string
+
+	if ok, err := network.Extensions.Get("x-incus-type", &nettype); !ok || err != nil {
+		nettype = c.Network.Type
+	}
+
+	if ok, err := network.Extensions.Get("x-incus-uplink", &uplink); !ok || err != nil {
+		uplink = c.Network.Uplink
+	}
+
+	slog.Info("Creating network",
+		slog.String("key", key),
+		slog.String("name", network.Name),
+		slog.String("type", nettype),
+		slog.String("uplink", uplink),
+	)
+
+	// Parse remote
+	resources, err := c.ParseServers(network.Name)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	var apiNetwork api.NetworksPost
+
+	apiNetwork.Name = network.Name
+	apiNetwork.Type = nettype
+	apiNetwork.Config = map[string]string{}
+
+	if nettype == "ovn" {
+		apiNetwork.Config["network"] = uplink
+
+		var ipv4 string
+		if ok, _ := network.Extensions.Get("x-incus-ipv4-address", &ipv4); ok {
+			apiNetwork.Config["ipv4.address"] = ipv4
+		}
+
+		var ipv6 string
+		if ok, _ := network.Extensions.Get("x-incus-ipv6-address", &ipv6); ok {
+			apiNetwork.Config["ipv6.address"] = ipv6
+		}
+
+		var nat bool
+		if ok, _ := network.Extensions.Get("x-incus-nat", &nat); ok {
+			apiNetwork.Config["ipv4.nat"] = boolToConfig(nat)
+		}
+
+		var dnsDomain string
+		if ok, _ := network.Extensions.Get("x-incus-dns-domain", &dnsDomain); ok {
+			apiNetwork.Config["dns.domain"] = dnsDomain
+		}
+
+		// ACLs are project-scoped objects independent of the network, so they
+		// can (and must) be reconciled before the network references them by
+		// name via security.acls - otherwise CreateNetworkACL makes a
+		// standalone object with no effect on any traffic.
+		var acls []ovnACL
+		if ok, _ := network.Extensions.Get("x-incus-ovn-acls", &acls); ok && len(acls) > 0 {
+			if err := c.reconcileACLs(client, network.Name, acls); err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(acls))
+			for _, acl := range acls {
+				names = append(names, acl.Name)
+			}
+			apiNetwork.Config["security.acls"] = strings.Join(names, ",")
+		}
+	}
+
+	// A release pins the exact Config a prior `up` computed and applied, so
+	// redeploys reconcile to that instead of recomputing from viper/extensions
+	// and potentially drifting. --no-pin (or INCUS_COMPOSE_NO_PIN) opts back
+	// into always recomputing, e.g. after editing a network's OVN extensions.
+	if !c.NoPin {
+		if pinned, ok := c.activeNetworkConfig(key); ok {
+			apiNetwork.Config = pinned
+		}
+	}
+
+	// Create the network, reconciling config for one that already exists
+	// rather than erroring, so re-running `up` after edits converges.
+	existing, _, err := client.GetNetwork(apiNetwork.Name)
+	if err != nil {
+		err = client.CreateNetwork(apiNetwork)
+		if err != nil {
+			return err
+		}
+	} else {
+		put := existing.Writable()
+		for k, v := range apiNetwork.Config {
+			put.Config[k] = v
+		}
+		err = client.UpdateNetwork(apiNetwork.Name, put, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	if nettype == "ovn" {
+		var forwards []ovnForward
+		if ok, _ := network.Extensions.Get("x-incus-ovn-forwards", &forwards); ok {
+			if err := c.reconcileForwards(client, network.Name, forwards); err != nil {
+				return err
+			}
+		}
+
+		var peers []ovnPeer
+		if ok, _ := network.Extensions.Get("x-incus-ovn-peers", &peers); ok {
+			if err := c.reconcilePeers(client, network.Name, peers); err != nil {
+				return err
+			}
+		}
+
+		var balancers []ovnLoadBalancer
+		if ok, _ := network.Extensions.Get("x-incus-ovn-load-balancers", &balancers); ok {
+			if err := c.reconcileLoadBalancers(client, network.Name, balancers); err != nil {
+				return err
+			}
+		}
+	}
+
+	slog.Info("Network created", slog.String("name", network.Name))
+	return nil
+}
+
+// forEachNetwork runs fn over every non-external network, bounded by
+// c.Parallelism, via a Scheduler/StatusBoard pair: networks don't depend on
+// one another, so the scheduler's graph is a single unordered layer, but
+// routing through it still gets cancellation-on-first-error and live
+// per-network status lines instead of a one-off semaphore loop.
+func (c *Compose) forEachNetwork(ctx context.Context, fn func(key string, network dockercompose.NetworkConfig) error) error {
+	networks := map[string]dockercompose.NetworkConfig{}
+	g := graph.New(graph.StringHash, graph.Directed(), graph.Acyclic())
 	for key, network := range c.ComposeProject.Networks {
 		if network.External {
 			continue
 		}
-		var nettype string
-		var uplink string
+		networks[key] = network
+		_ = g.AddVertex(key)
+	}
+
+	names := make([]string, 0, len(networks))
+	for key := range networks {
+		names = append(names, key)
+	}
+	board := NewStatusBoard(names)
 
-		if ok, err := network.Extensions.Get("x-incus-type", &nettype); !ok || err != nil {
-			nettype = c.Network.Type
+	sched := NewScheduler(g, c.Parallelism)
+	return sched.Run(ctx, func(ctx context.Context, key string) error {
+		board.Set(key, StateRunning)
+		if err := fn(key, networks[key]); err != nil {
+			board.Set(key, StateFailed)
+			return err
 		}
+		board.Set(key, StateDone)
+		return nil
+	})
+}
 
-		if ok, err := network.Extensions.Get("x-incus-uplink", &uplink); !ok || err != nil {
-			uplink = c.Network.Uplink
+// reconcileACLs GET-then-PATCHes each ACL declared for network so that
+// editing x-incus-ovn-acls and re-running `up` converges instead of failing
+// on "already exists".
+func (c *Compose) reconcileACLs(client incus.InstanceServer, network string, acls []ovnACL) error {
+	for _, acl := range acls {
+		post := api.NetworkACLsPost{
+			NetworkACLPost: api.NetworkACLPost{Name: acl.Name},
+			NetworkACLPut: api.NetworkACLPut{
+				Description: acl.Description,
+				Ingress:     parseACLRules(acl.Ingress),
+				Egress:      parseACLRules(acl.Egress),
+			},
 		}
 
-		slog.Info("Creating network",
-			slog.String("key", key),
-			slog.String("name", network.Name),
-			slog.String("type", nettype),
-			slog.String("uplink", uplink),
-		)
+		slog.Info("Reconciling network ACL", slog.String("network", network), slog.String("name", acl.Name))
 
-		var apiNetwork api.NetworksPost
+		_, _, err := client.GetNetworkACL(acl.Name)
+		if err != nil {
+			if err := client.CreateNetworkACL(post); err != nil {
+				return err
+			}
+			continue
+		}
 
-		apiNetwork.Name = network.Name
-		apiNetwork.Type = nettype
-		apiNetwork.Config = map[string]string{}
+		if err := client.UpdateNetworkACL(acl.Name, post.NetworkACLPut, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-		if nettype == "ovn" {
-			apiNetwork.Config["network"] = uplink
+// reconcileForwards GET-then-PATCHes each forward declared for network.
+func (c *Compose) reconcileForwards(client incus.InstanceServer, network string, forwards []ovnForward) error {
+	for _, fwd := range forwards {
+		put := api.NetworkForwardPut{
+			Description: fwd.Description,
+			Ports:       parseForwardPorts(fwd.Ports),
 		}
 
-		// Parse remote
-		resources, err := c.ParseServers(network.Name)
+		slog.Info("Reconciling network forward", slog.String("network", network), slog.String("listen_address", fwd.ListenAddress))
+
+		_, _, err := client.GetNetworkForward(network, fwd.ListenAddress)
 		if err != nil {
+			post := api.NetworkForwardsPost{
+				ListenAddress:     fwd.ListenAddress,
+				NetworkForwardPut: put,
+			}
+			if err := client.CreateNetworkForward(network, post); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := client.UpdateNetworkForward(network, fwd.ListenAddress, put, ""); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
 
-		resource := resources[0]
-		client := resource.server
+// reconcilePeers GET-then-PATCHes each OVN network peering declared for network.
+func (c *Compose) reconcilePeers(client incus.InstanceServer, network string, peers []ovnPeer) error {
+	for _, peer := range peers {
+		put := api.NetworkPeerPut{
+			TargetProject: peer.TargetProject,
+			TargetNetwork: peer.TargetNetwork,
+		}
 
-		// Create the network
-		err = client.CreateNetwork(apiNetwork)
+		slog.Info("Reconciling network peer", slog.String("network", network), slog.String("name", peer.Name))
+
+		_, _, err := client.GetNetworkPeer(network, peer.Name)
 		if err != nil {
+			post := api.NetworkPeersPost{
+				Name:          peer.Name,
+				NetworkPeerPut: put,
+			}
+			if err := client.CreateNetworkPeer(network, post); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := client.UpdateNetworkPeer(network, peer.Name, put, ""); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// reconcileLoadBalancers GET-then-PATCHes each load balancer declared for network.
+func (c *Compose) reconcileLoadBalancers(client incus.InstanceServer, network string, balancers []ovnLoadBalancer) error {
+	for _, lb := range balancers {
+		put := api.NetworkLoadBalancerPut{
+			Backends: parseLoadBalancerBackends(lb.Backends),
+			Ports:    parseLoadBalancerPorts(lb.Ports),
+		}
+
+		slog.Info("Reconciling network load balancer", slog.String("network", network), slog.String("listen_address", lb.ListenAddress))
+
+		_, _, err := client.GetNetworkLoadBalancer(network, lb.ListenAddress)
+		if err != nil {
+			post := api.NetworkLoadBalancersPost{
+				ListenAddress:          lb.ListenAddress,
+				NetworkLoadBalancerPut: put,
+			}
+			if err := client.CreateNetworkLoadBalancer(network, post); err != nil {
+				return err
+			}
+			continue
+		}
 
-		slog.Info("Network created", slog.String("name", network.Name))
+		if err := client.UpdateNetworkLoadBalancer(network, lb.ListenAddress, put, ""); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// DestroyNetworks destroys the default network for a stack
-func (c *Compose) DestroyNetworks() error {
+// DestroyNetworks destroys every non-external network declared in the
+// project, tearing down its ACLs/forwards/peers/load balancers first since
+// Incus refuses to delete a network with dependents still attached.
+// Networks are torn down concurrently, bounded by c.Parallelism.
+func (c *Compose) DestroyNetworks(ctx context.Context) error {
 	slog.Info("Destroying networks")
 
+	return c.forEachNetwork(ctx, c.destroyNetwork)
+}
+
+func (c *Compose) destroyNetwork(key string, network dockercompose.NetworkConfig) error {
+	slog.Info("Destroying network", slog.String("key", key), slog.String("name", network.Name))
+
+	resources, err := c.ParseServers(network.Name)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
 	var funcError error
 
-	for key, network := range c.ComposeProject.Networks {
-		if network.External {
-			continue
+	// Dependents must go before the network itself, or Incus refuses to
+	// delete it.
+	if err := destroyLoadBalancers(client, network.Name); err != nil {
+		funcError = errors.Join(funcError, err)
+	}
+	if err := destroyForwards(client, network.Name); err != nil {
+		funcError = errors.Join(funcError, err)
+	}
+	if err := destroyPeers(client, network.Name); err != nil {
+		funcError = errors.Join(funcError, err)
+	}
+
+	var acls []ovnACL
+	if ok, _ := network.Extensions.Get("x-incus-ovn-acls", &acls); ok {
+		if err := destroyACLs(client, acls); err != nil {
+			funcError = errors.Join(funcError, err)
 		}
+	}
 
-		slog.Info("Destroying network", slog.String("key", key), slog.String("name", network.Name))
+	// Delete the network
+	if err := resource.server.DeleteNetwork(resource.name); err != nil {
+		funcError = errors.Join(funcError, err)
+	}
 
-		resources, err := c.ParseServers(network.Name)
-		if err != nil {
+	slog.Info("Destroyed network", slog.String("name", network.Name))
+	return funcError
+}
+
+func destroyACLs(client incus.InstanceServer, acls []ovnACL) error {
+	var funcError error
+	for _, acl := range acls {
+		if err := client.DeleteNetworkACL(acl.Name); err != nil {
 			funcError = errors.Join(funcError, err)
 		}
+	}
+	return funcError
+}
 
-		resource := resources[0]
+func destroyForwards(client incus.InstanceServer, network string) error {
+	names, err := client.GetNetworkForwardAddresses(network)
+	if err != nil {
+		return err
+	}
 
-		// Delete the network
-		err = resource.server.DeleteNetwork(resource.name)
-		if err != nil {
+	var funcError error
+	for _, listenAddress := range names {
+		if err := client.DeleteNetworkForward(network, listenAddress); err != nil {
+			funcError = errors.Join(funcError, err)
+		}
+	}
+	return funcError
+}
+
+func destroyPeers(client incus.InstanceServer, network string) error {
+	names, err := client.GetNetworkPeerNames(network)
+	if err != nil {
+		return err
+	}
+
+	var funcError error
+	for _, name := range names {
+		if err := client.DeleteNetworkPeer(network, name); err != nil {
+			funcError = errors.Join(funcError, err)
+		}
+	}
+	return funcError
+}
+
+func destroyLoadBalancers(client incus.InstanceServer, network string) error {
+	names, err := client.GetNetworkLoadBalancerAddresses(network)
+	if err != nil {
+		return err
+	}
+
+	var funcError error
+	for _, listenAddress := range names {
+		if err := client.DeleteNetworkLoadBalancer(network, listenAddress); err != nil {
 			funcError = errors.Join(funcError, err)
 		}
-		slog.Info("Destroyed network", slog.String("name", network.Name))
 	}
 	return funcError
 }
+
+func boolToConfig(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// parseACLRules translates the rules declared under x-incus-ovn-acls into
+// api.NetworkACLRule, defaulting Action to "allow" only when the user left
+// it unset, never overriding an explicit "reject"/"drop".
+func parseACLRules(rules []ovnACLRule) []api.NetworkACLRule {
+	out := make([]api.NetworkACLRule, 0, len(rules))
+	for _, rule := range rules {
+		action := rule.Action
+		if action == "" {
+			action = "allow"
+		}
+		out = append(out, api.NetworkACLRule{
+			Action:          action,
+			Source:          rule.Source,
+			Destination:     rule.Destination,
+			Protocol:        rule.Protocol,
+			DestinationPort: rule.Ports,
+			Description:     rule.Description,
+			State:           "enabled",
+		})
+	}
+	return out
+}
+
+func parseForwardPorts(ports []ovnForwardPort) []api.NetworkForwardPort {
+	out := make([]api.NetworkForwardPort, 0, len(ports))
+	for _, p := range ports {
+		out = append(out, api.NetworkForwardPort{
+			ListenPort:    p.ListenPort,
+			Protocol:      p.Protocol,
+			TargetAddress: p.TargetAddress,
+			TargetPort:    p.TargetPort,
+		})
+	}
+	return out
+}
+
+func parseLoadBalancerBackends(backends []ovnLoadBalancerBackend) []api.NetworkLoadBalancerBackend {
+	out := make([]api.NetworkLoadBalancerBackend, 0, len(backends))
+	for _, b := range backends {
+		out = append(out, api.NetworkLoadBalancerBackend{
+			Name:          b.Name,
+			TargetAddress: b.TargetAddress,
+			TargetPort:    b.TargetPort,
+		})
+	}
+	return out
+}
+
+func parseLoadBalancerPorts(ports []ovnLoadBalancerPort) []api.NetworkLoadBalancerPort {
+	out := make([]api.NetworkLoadBalancerPort, 0, len(ports))
+	for _, p := range ports {
+		out = append(out, api.NetworkLoadBalancerPort{
+			ListenPort:    p.ListenPort,
+			Protocol:      p.Protocol,
+			TargetBackend: p.TargetBackend,
+		})
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package application
+
+import "testing"
+
+func TestParseACLRulesPreservesExplicitAction(t *testing.T) {
+	rules := parseACLRules([]ovnACLRule{
+		{Action: "deny", Protocol: "tcp", Ports: "22", Source: "0.0.0.0/0"},
+		{Protocol: "tcp", Ports: "443"},
+	})
+
+	if rules[0].Action != "deny" {
+		t.Errorf("explicit action was not preserved: got %q, want deny", rules[0].Action)
+	}
+	if rules[0].DestinationPort != "22" {
+		t.Errorf("DestinationPort = %q, want 22", rules[0].DestinationPort)
+	}
+	if rules[1].Action != "allow" {
+		t.Errorf("unset action should default to allow: got %q", rules[1].Action)
+	}
+}
+
+func TestParseForwardPortsThreadsTarget(t *testing.T) {
+	ports := parseForwardPorts([]ovnForwardPort{
+		{ListenPort: "80", Protocol: "tcp", TargetAddress: "10.0.0.5", TargetPort: "8080"},
+	})
+
+	if ports[0].TargetAddress != "10.0.0.5" || ports[0].TargetPort != "8080" {
+		t.Errorf("forward port missing target: %+v", ports[0])
+	}
+}
+
+func TestParseLoadBalancerPortsReferenceBackends(t *testing.T) {
+	backends := parseLoadBalancerBackends([]ovnLoadBalancerBackend{
+		{Name: "web-1", TargetAddress: "10.0.0.5", TargetPort: "8080"},
+	})
+	ports := parseLoadBalancerPorts([]ovnLoadBalancerPort{
+		{ListenPort: "443", Protocol: "tcp", TargetBackend: []string{"web-1"}},
+	})
+
+	if backends[0].Name != "web-1" {
+		t.Errorf("backend Name = %q, want web-1", backends[0].Name)
+	}
+	if len(ports[0].TargetBackend) != 1 || ports[0].TargetBackend[0] != "web-1" {
+		t.Errorf("port does not reference backend by name: %+v", ports[0])
+	}
+}
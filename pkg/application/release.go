@@ -0,0 +1,203 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bketelsen/incus-compose/pkg/release"
+	api "github.com/lxc/incus/v6/shared/api"
+	"gopkg.in/yaml.v3"
+)
+
+// CaptureRelease records the stack that was just successfully brought up:
+// the merged compose YAML, the resolved image fingerprint for every
+// service, and the exact network config pushed to Incus. It is called at
+// the end of a successful `up` so `release ls`/`rollback` have something to
+// work from.
+func (c *Compose) CaptureRelease(timestamp int64, shortSHA string) (*release.Release, error) {
+	composeYAML, err := yaml.Marshal(c.ComposeProject)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := &release.Release{
+		Timestamp: timestamp,
+		ShortSHA:  shortSHA,
+		Compose:   string(composeYAML),
+		Images:    map[string]string{},
+		Profiles:  map[string][]string{},
+		Networks:  map[string]release.Config{},
+	}
+
+	for name := range c.ComposeProject.Services {
+		resources, err := c.ParseServers(name)
+		if err != nil {
+			return nil, err
+		}
+		resource := resources[0]
+
+		inst, _, err := resource.server.GetInstance(resource.name)
+		if err != nil {
+			return nil, fmt.Errorf("capturing release for service %s: %w", name, err)
+		}
+		rel.Images[name] = inst.Config["volatile.base_image"]
+		rel.Profiles[name] = inst.Profiles
+	}
+
+	for name, network := range c.ComposeProject.Networks {
+		if network.External {
+			continue
+		}
+
+		resources, err := c.ParseServers(network.Name)
+		if err != nil {
+			return nil, err
+		}
+		resource := resources[0]
+
+		apiNet, _, err := resource.server.GetNetwork(resource.name)
+		if err != nil {
+			return nil, fmt.Errorf("capturing release for network %s: %w", name, err)
+		}
+
+		rel.Networks[name] = release.Config{Type: apiNet.Type, Config: apiNet.Config}
+	}
+
+	if err := release.Save(c.ProjectDir, rel); err != nil {
+		return nil, err
+	}
+
+	resources, err := c.ParseServers("")
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) > 0 {
+		client := resources[0].server
+
+		info, err := client.GetConnectionInfo()
+		if err != nil {
+			return nil, fmt.Errorf("recording active release: %w", err)
+		}
+
+		proj, etag, err := client.GetProject(info.Project)
+		if err != nil {
+			return nil, fmt.Errorf("recording active release on project %s: %w", info.Project, err)
+		}
+
+		put := proj.Writable()
+		if put.Config == nil {
+			put.Config = map[string]string{}
+		}
+		put.Config[release.ConfigKey] = shortSHA
+
+		if err := client.UpdateProject(info.Project, put, etag); err != nil {
+			return nil, fmt.Errorf("recording active release on project %s: %w", info.Project, err)
+		}
+	}
+
+	slog.Info("Captured release", slog.String("sha", shortSHA), slog.Time("at", time.UnixMilli(timestamp)))
+
+	return rel, nil
+}
+
+// activeNetworkConfig returns the exact Config a release pinned for
+// network, when a release exists and recorded one, so CreateNetworks can
+// reuse it instead of recomputing from viper/extensions and drifting.
+func (c *Compose) activeNetworkConfig(network string) (map[string]string, bool) {
+	rel, err := release.Active(c.ProjectDir)
+	if err != nil || rel == nil {
+		return nil, false
+	}
+
+	cfg, ok := rel.Networks[network]
+	if !ok {
+		return nil, false
+	}
+
+	return cfg.Config, true
+}
+
+// Rollback replays the release recorded under shortSHA: every network's
+// config is pinned back to the stored values, and every service is rebuilt
+// from its recorded image fingerprint with its recorded profile list, so
+// the instance's actual rootfs - not just its volatile.base_image label -
+// matches what was running at that release.
+func (c *Compose) Rollback(shortSHA string) error {
+	rel, err := release.Find(c.ProjectDir, shortSHA)
+	if err != nil {
+		return err
+	}
+
+	var funcError error
+
+	for name, cfg := range rel.Networks {
+		// rel.Networks is keyed by compose key; Incus knows the network by
+		// its compose Name.
+		netName := name
+		if network, ok := c.ComposeProject.Networks[name]; ok && network.Name != "" {
+			netName = network.Name
+		}
+		resources, err := c.ParseServers(netName)
+		if err != nil {
+			funcError = errors.Join(funcError, err)
+			continue
+		}
+		resource := resources[0]
+
+		existing, _, err := resource.server.GetNetwork(resource.name)
+		if err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back network %s: %w", name, err))
+			continue
+		}
+
+		put := existing.Writable()
+		put.Config = cfg.Config
+		if err := resource.server.UpdateNetwork(resource.name, put, ""); err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back network %s: %w", name, err))
+		}
+	}
+
+	for name, image := range rel.Images {
+		resources, err := c.ParseServers(name)
+		if err != nil {
+			funcError = errors.Join(funcError, err)
+			continue
+		}
+		resource := resources[0]
+
+		_, etag, err := resource.server.GetInstance(resource.name)
+		if err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back service %s: %w", name, err))
+			continue
+		}
+
+		rebuild := api.InstanceRebuildPost{
+			Source: api.InstanceSource{
+				Type:        "image",
+				Fingerprint: image,
+			},
+		}
+		if err := resource.server.RebuildInstance(resource.name, rebuild, etag); err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back service %s: %w", name, err))
+			continue
+		}
+
+		inst, etag, err := resource.server.GetInstance(resource.name)
+		if err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back service %s: %w", name, err))
+			continue
+		}
+		put := inst.Writable()
+		put.Profiles = rel.Profiles[name]
+		if err := resource.server.UpdateInstance(resource.name, put, etag); err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("rolling back service %s: %w", name, err))
+		}
+	}
+
+	if funcError == nil {
+		slog.Info("Rolled back to release", slog.String("sha", shortSHA))
+	}
+	return funcError
+}
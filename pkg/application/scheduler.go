@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/dominikbraun/graph"
+)
+
+// Scheduler walks a graph in topological layers, running the nodes within a
+// layer concurrently up to Parallelism workers, so independent work (e.g.
+// the networks in CreateNetworks/DestroyNetworks) runs together instead of
+// one at a time.
+//
+// This is currently wired into network create/destroy only. Instance
+// create/start/stop/destroy would be the other natural caller (walking
+// app.Dag in service-dependency order), but that lifecycle isn't
+// implemented anywhere in this tree yet, so there is nothing to wire it
+// into - networks have no inter-dependencies, so Scheduler degrades to a
+// single layer for them, same as the semaphore-bounded loop it replaced.
+type Scheduler struct {
+	Dag         graph.Graph[string, string]
+	Parallelism int
+}
+
+// NewScheduler builds a Scheduler over dag, defaulting parallelism to 1
+// when given a non-positive value so callers that forget --parallel still
+// get correct (if serial) behaviour.
+func NewScheduler(dag graph.Graph[string, string], parallelism int) *Scheduler {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &Scheduler{Dag: dag, Parallelism: parallelism}
+}
+
+// Run calls work once per node in dependency order: a node only starts once
+// every node it depends on (its DependsOn edges) has completed. Nodes with
+// no relative ordering between them run concurrently, bounded by
+// Parallelism. If ctx is cancelled, or any call to work returns an error,
+// nodes that haven't started yet are skipped rather than started; already
+// running nodes are given the chance to observe ctx.Err() and stop
+// themselves. All resulting errors are combined with errors.Join.
+func (s *Scheduler) Run(ctx context.Context, work func(ctx context.Context, node string) error) error {
+	layers, err := graph.StableTopologicalSort(s.Dag, func(a, b string) bool { return a < b })
+	if err != nil {
+		return err
+	}
+
+	// Dependencies in this DAG point from a node to what it depends on, so
+	// a node is runnable once every node it points to has finished.
+	remaining := map[string][]string{}
+	for _, node := range layers {
+		deps, _ := s.Dag.AdjacencyMap()
+		edges := deps[node]
+		for dep := range edges {
+			remaining[node] = append(remaining[node], dep)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		done    = map[string]bool{}
+		funcErr error
+		sem     = make(chan struct{}, s.Parallelism)
+		wg      sync.WaitGroup
+		abort   bool
+	)
+
+	ready := func(node string) bool {
+		for _, dep := range remaining[node] {
+			if !done[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	pending := append([]string(nil), layers...)
+	for len(pending) > 0 {
+		mu.Lock()
+		var runnable []string
+		var stillPending []string
+		for _, node := range pending {
+			if abort || ctx.Err() != nil {
+				continue
+			}
+			if ready(node) {
+				runnable = append(runnable, node)
+			} else {
+				stillPending = append(stillPending, node)
+			}
+		}
+		pending = stillPending
+		mu.Unlock()
+
+		if len(runnable) == 0 {
+			break
+		}
+
+		for _, node := range runnable {
+			node := node
+			sem <- struct{}{}
+			if ctx.Err() != nil {
+				<-sem
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				err := work(ctx, node)
+
+				mu.Lock()
+				defer mu.Unlock()
+				done[node] = true
+				if err != nil {
+					funcErr = errors.Join(funcErr, err)
+					abort = true
+					cancel()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	if funcErr == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return funcErr
+}
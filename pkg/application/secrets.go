@@ -0,0 +1,202 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bketelsen/incus-compose/pkg/secrets"
+	"github.com/dominikbraun/graph"
+	api "github.com/lxc/incus/v6/shared/api"
+)
+
+// SecretsExtensionKey is the compose extension a service uses to declare
+// the secrets it needs resolved and injected at deploy time.
+const SecretsExtensionKey = "x-incus-secrets"
+
+// ResolveSecrets walks every service, resolves its declared x-incus-secrets
+// against the configured backend, and pushes each resolved value into the
+// running instance: as a config-mapped file via `incus file push` when the
+// target is a path, or as an `environment.*` config key otherwise. Nothing
+// is ever written to disk in the project directory.
+func (c *Compose) ResolveSecrets() error {
+	slog.Info("Resolving secrets")
+
+	resolver := secrets.NewResolver(c.SecretsBackend)
+
+	var funcError error
+
+	for name, service := range c.ComposeProject.Services {
+		var specs []secrets.Spec
+		if ok, err := service.Extensions.Get(SecretsExtensionKey, &specs); !ok || err != nil {
+			continue
+		}
+
+		resources, err := c.ParseServers(name)
+		if err != nil {
+			funcError = errors.Join(funcError, err)
+			continue
+		}
+		resource := resources[0]
+
+		for _, spec := range specs {
+			value, err := resolver.Resolve(spec)
+			if err != nil {
+				funcError = errors.Join(funcError, fmt.Errorf("service %s: %w", name, err))
+				continue
+			}
+
+			slog.Info("Injecting secret", slog.String("service", name), slog.String("secret", spec.Name))
+
+			if spec.IsFile() {
+				args := api.InstanceFileArgs{
+					Content: bytes.NewReader([]byte(value)),
+					UID:     int64(spec.UID),
+					GID:     int64(spec.GID),
+					Mode:    parseFileMode(spec.Mode),
+					Type:    "file",
+				}
+				if err := resource.server.CreateInstanceFile(resource.name, spec.Target, args); err != nil {
+					funcError = errors.Join(funcError, fmt.Errorf("service %s: pushing secret %s: %w", name, spec.Name, err))
+				}
+				continue
+			}
+
+			inst, etag, err := resource.server.GetInstance(resource.name)
+			if err != nil {
+				funcError = errors.Join(funcError, fmt.Errorf("service %s: %w", name, err))
+				continue
+			}
+			if inst.Config == nil {
+				inst.Config = map[string]string{}
+			}
+			inst.Config["environment."+spec.Target] = value
+			if err := resource.server.UpdateInstance(resource.name, inst.Writable(), etag); err != nil {
+				funcError = errors.Join(funcError, fmt.Errorf("service %s: setting secret %s: %w", name, spec.Name, err))
+			}
+		}
+	}
+
+	return funcError
+}
+
+// RestartDependents restarts every service whose x-incus-secrets reference
+// secretPath, plus everything that transitively depends on them in
+// c.Dag, so a rotated secret takes effect without the caller having to
+// figure out the blast radius by hand.
+func (c *Compose) RestartDependents(secretPath string) error {
+	affected := map[string]bool{}
+
+	for name, service := range c.ComposeProject.Services {
+		var specs []secrets.Spec
+		if ok, err := service.Extensions.Get(SecretsExtensionKey, &specs); !ok || err != nil {
+			continue
+		}
+		for _, spec := range specs {
+			if _, value, _ := cutScheme(spec.Source); value == secretPath {
+				affected[name] = true
+			}
+		}
+	}
+
+	if len(affected) == 0 {
+		slog.Info("No services reference secret, nothing to restart", slog.String("secret", secretPath))
+		return nil
+	}
+
+	if err := expandToDependents(c.Dag, affected); err != nil {
+		return fmt.Errorf("expanding restart set: %w", err)
+	}
+
+	order, err := graph.StableTopologicalSort(c.Dag, func(a, b string) bool { return a < b })
+	if err != nil {
+		return fmt.Errorf("ordering restart: %w", err)
+	}
+
+	var funcError error
+	for _, name := range order {
+		if !affected[name] {
+			continue
+		}
+
+		slog.Info("Restarting service for rotated secret", slog.String("service", name), slog.String("secret", secretPath))
+
+		resources, err := c.ParseServers(name)
+		if err != nil {
+			funcError = errors.Join(funcError, err)
+			continue
+		}
+		resource := resources[0]
+
+		if err := resource.server.UpdateInstanceState(resource.name, api.InstanceStatePut{Action: "restart", Force: true}, ""); err != nil {
+			funcError = errors.Join(funcError, fmt.Errorf("restarting %s: %w", name, err))
+		}
+	}
+
+	return funcError
+}
+
+// expandToDependents grows affected, in place, to also include every node
+// that transitively depends on a node already in the set: edges in dag point
+// from a service to what it depends on, so a dependent is found by walking
+// those edges in reverse.
+func expandToDependents(dag graph.Graph[string, string], affected map[string]bool) error {
+	adjacency, err := dag.AdjacencyMap()
+	if err != nil {
+		return err
+	}
+
+	dependents := map[string][]string{}
+	for node, deps := range adjacency {
+		for dep := range deps {
+			dependents[dep] = append(dependents[dep], node)
+		}
+	}
+
+	queue := make([]string, 0, len(affected))
+	for name := range affected {
+		queue = append(queue, name)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range dependents[name] {
+			if affected[dependent] {
+				continue
+			}
+			affected[dependent] = true
+			queue = append(queue, dependent)
+		}
+	}
+
+	return nil
+}
+
+// cutScheme splits a secret Source of the form "scheme:value" the same way
+// secrets.Resolver does, used here only to match against a rotated path.
+func cutScheme(source string) (scheme, value string, ok bool) {
+	for i := 0; i < len(source); i++ {
+		if source[i] == ':' {
+			return source[:i], source[i+1:], true
+		}
+	}
+	return "", source, false
+}
+
+// parseFileMode maps the string mode declared under x-incus-secrets
+// (e.g. "0400") to the numeric mode incus file push expects, defaulting to
+// 0400 so secret files aren't world-readable by accident.
+func parseFileMode(mode string) int {
+	if mode == "" {
+		return 0400
+	}
+	var m int
+	_, _ = fmt.Sscanf(mode, "%o", &m)
+	if m == 0 {
+		return 0400
+	}
+	return m
+}
@@ -0,0 +1,82 @@
+package application
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// State is one stage of a node's lifecycle as it moves through a Scheduler
+// run.
+type State string
+
+const (
+	StatePending State = "pending"
+	StateRunning State = "running"
+	StateDone    State = "done"
+	StateFailed  State = "failed"
+)
+
+// StatusBoard renders a single, redrawn-in-place line per node, the way
+// `up`/`down` want to show pending/creating/started/failed without
+// scrolling the terminal once per node.
+type StatusBoard struct {
+	mu     sync.Mutex
+	order  []string
+	status map[string]State
+	lines  int
+}
+
+// NewStatusBoard creates a board that will track exactly the given nodes,
+// printed in a stable, alphabetised order regardless of completion order.
+func NewStatusBoard(nodes []string) *StatusBoard {
+	order := append([]string(nil), nodes...)
+	sort.Strings(order)
+
+	status := make(map[string]State, len(order))
+	for _, n := range order {
+		status[n] = StatePending
+	}
+
+	return &StatusBoard{order: order, status: status}
+}
+
+// Set updates node's state and redraws the board.
+func (b *StatusBoard) Set(node string, state State) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.status[node] = state
+	b.render()
+}
+
+// render rewrites the previously printed block in place using ANSI cursor
+// movement; incus-compose already assumes an interactive terminal for its
+// tint-based logging, so no non-terminal fallback is attempted here.
+func (b *StatusBoard) render() {
+	if b.lines > 0 {
+		fmt.Fprintf(os.Stderr, "\x1b[%dA", b.lines)
+	}
+
+	for _, node := range b.order {
+		fmt.Fprintf(os.Stderr, "\x1b[2K%-24s %s\n", node, symbolFor(b.status[node]))
+	}
+	b.lines = len(b.order)
+}
+
+func symbolFor(s State) string {
+	switch s {
+	case StatePending:
+		return strings.Repeat(".", 1) + " pending"
+	case StateRunning:
+		return "- creating"
+	case StateDone:
+		return "+ started"
+	case StateFailed:
+		return "x failed"
+	default:
+		return string(s)
+	}
+}
@@ -0,0 +1,120 @@
+// Package lint statically analyses a loaded compose project before any
+// Incus API calls are made, surfacing problems (cycles, dangling
+// references, limits Incus itself would reject) as numbered, suppressible
+// findings.
+package lint
+
+import (
+	"github.com/bketelsen/incus-compose/pkg/application"
+)
+
+// Level is the severity a Rule reports at by default.
+type Level string
+
+const (
+	// LevelError fails the lint run unless the rule is ignored.
+	LevelError Level = "error"
+	// LevelWarning is reported but only fails the run under --strict.
+	LevelWarning Level = "warning"
+)
+
+// IgnoreExtensionKey is how a project or service opts a rule out, e.g.
+// `x-incus-lint-ignore: [R014]`.
+const IgnoreExtensionKey = "x-incus-lint-ignore"
+
+// Finding is a single problem a Rule found.
+type Finding struct {
+	RuleID  string
+	Level   Level
+	Service string // empty for project-wide findings
+	Message string
+}
+
+// Rule is one numbered, independently suppressible check.
+type Rule struct {
+	ID          string
+	Level       Level
+	Description string
+	Check       func(*application.Compose) []Finding
+}
+
+// Rules is the built-in ruleset, in the order `lint` reports them.
+var Rules = []Rule{
+	ruleDAGCycles,
+	ruleDanglingDependsOn,
+	ruleOVNWithoutUplink,
+	ruleInstanceNameLength,
+	ruleDuplicateDeviceNames,
+	ruleMissingImage,
+	ruleUndeclaredSecrets,
+	ruleVolumePoolMismatch,
+}
+
+// Run executes every rule in Rules whose ID is in only (all of them when
+// only is empty), dropping findings suppressed via x-incus-lint-ignore at
+// the project or service level.
+func Run(c *application.Compose, only []string) []Finding {
+	wanted := map[string]bool{}
+	for _, id := range only {
+		wanted[id] = true
+	}
+
+	ignored := ignoredRules(c)
+
+	var findings []Finding
+	for _, rule := range Rules {
+		if len(wanted) > 0 && !wanted[rule.ID] {
+			continue
+		}
+
+		for _, f := range rule.Check(c) {
+			if f.RuleID == "" {
+				f.RuleID = rule.ID
+			}
+			if f.Level == "" {
+				f.Level = rule.Level
+			}
+			if ignored[f.RuleID] || (f.Service != "" && ignored[f.Service+"/"+f.RuleID]) {
+				continue
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
+
+// ignoredRules collects the project-wide x-incus-lint-ignore list plus a
+// per-service "service/RULE" entry for every service-level list, so Run can
+// check both with a single map lookup.
+func ignoredRules(c *application.Compose) map[string]bool {
+	ignored := map[string]bool{}
+
+	var projectIgnores []string
+	if ok, err := c.ComposeProject.Extensions.Get(IgnoreExtensionKey, &projectIgnores); ok && err == nil {
+		for _, id := range projectIgnores {
+			ignored[id] = true
+		}
+	}
+
+	for name, service := range c.ComposeProject.Services {
+		var serviceIgnores []string
+		if ok, err := service.Extensions.Get(IgnoreExtensionKey, &serviceIgnores); ok && err == nil {
+			for _, id := range serviceIgnores {
+				ignored[name+"/"+id] = true
+			}
+		}
+	}
+
+	return ignored
+}
+
+// HasErrors reports whether any finding in findings is at LevelError.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Level == LevelError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bketelsen/incus-compose/pkg/application"
+	"github.com/bketelsen/incus-compose/pkg/secrets"
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+	"github.com/dominikbraun/graph"
+)
+
+var ruleDAGCycles = Rule{
+	ID:          "R001",
+	Level:       LevelError,
+	Description: "the service dependency graph must not contain a cycle",
+	Check: func(c *application.Compose) []Finding {
+		if c.Dag == nil {
+			return nil
+		}
+		if _, err := graph.TopologicalSort(c.Dag); err != nil {
+			return []Finding{{Message: fmt.Sprintf("depends_on graph contains a cycle: %s", err)}}
+		}
+		return nil
+	},
+}
+
+var ruleDanglingDependsOn = Rule{
+	ID:          "R002",
+	Level:       LevelError,
+	Description: "depends_on must reference a service defined in this project",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name, svc := range c.Services {
+			for _, dep := range svc.DependsOn {
+				if _, ok := c.Services[dep]; !ok {
+					findings = append(findings, Finding{
+						Service: name,
+						Message: fmt.Sprintf("depends_on references undefined service %q", dep),
+					})
+				}
+			}
+		}
+		return findings
+	},
+}
+
+var ruleOVNWithoutUplink = Rule{
+	ID:          "R003",
+	Level:       LevelError,
+	Description: "an ovn network must have an uplink configured",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for key, network := range c.ComposeProject.Networks {
+			var nettype string
+			if ok, err := network.Extensions.Get("x-incus-type", &nettype); !ok || err != nil {
+				nettype = c.Network.Type
+			}
+			if nettype != "ovn" {
+				continue
+			}
+
+			var uplink string
+			if ok, err := network.Extensions.Get("x-incus-uplink", &uplink); !ok || err != nil {
+				uplink = c.Network.Uplink
+			}
+			if uplink == "" {
+				findings = append(findings, Finding{
+					Message: fmt.Sprintf("network %q is type ovn but has no uplink (set x-incus-uplink or --network-uplink)", key),
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// maxInstanceNameLength is the limit Incus enforces on instance names.
+const maxInstanceNameLength = 63
+
+var ruleInstanceNameLength = Rule{
+	ID:          "R004",
+	Level:       LevelError,
+	Description: "hostname+project combinations must fit in Incus's 63 character instance name limit",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name := range c.ComposeProject.Services {
+			full := fmt.Sprintf("%s-%s", c.ComposeProject.Name, name)
+			if len(full) > maxInstanceNameLength {
+				findings = append(findings, Finding{
+					Service: name,
+					Message: fmt.Sprintf("instance name %q is %d characters, over Incus's %d character limit", full, len(full), maxInstanceNameLength),
+				})
+			}
+		}
+		return findings
+	},
+}
+
+var ruleDuplicateDeviceNames = Rule{
+	ID:          "R005",
+	Level:       LevelWarning,
+	Description: "device names declared via x-incus-devices should not collide across a service's profiles",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name, svc := range c.ComposeProject.Services {
+			// x-incus-devices is keyed by profile name, each mapping to its
+			// own device-name -> device-config map, since Incus applies
+			// profiles (and the devices they carry) in order and a name
+			// reused across two profiles silently shadows the earlier one.
+			var profiles map[string]map[string]map[string]string
+			if ok, err := svc.Extensions.Get("x-incus-devices", &profiles); !ok || err != nil {
+				continue
+			}
+
+			owner := map[string]string{}
+			for profile, devices := range profiles {
+				for deviceName := range devices {
+					if first, ok := owner[deviceName]; ok {
+						findings = append(findings, Finding{
+							Service: name,
+							Message: fmt.Sprintf("device %q is declared in both profile %q and profile %q", deviceName, first, profile),
+						})
+						continue
+					}
+					owner[deviceName] = profile
+				}
+			}
+		}
+		return findings
+	},
+}
+
+var ruleMissingImage = Rule{
+	ID:          "R006",
+	Level:       LevelError,
+	Description: "a service must declare the Incus image to launch via x-incus-image",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name, svc := range c.ComposeProject.Services {
+			var image string
+			if ok, err := svc.Extensions.Get("x-incus-image", &image); !ok || err != nil || image == "" {
+				findings = append(findings, Finding{
+					Service: name,
+					Message: "service has no x-incus-image set",
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// secretRefPattern matches the `${secret:NAME}` syntax a service uses to
+// reference a secret from its own environment/volume values, distinct from
+// ordinary compose variable interpolation (which compose-go already
+// resolves before extensions are read).
+var secretRefPattern = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.\-/]+)\}`)
+
+var ruleUndeclaredSecrets = Rule{
+	ID:          "R007",
+	Level:       LevelError,
+	Description: "a secret referenced via ${secret:NAME} must be declared under x-incus-secrets",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name, svc := range c.ComposeProject.Services {
+			var declared []secrets.Spec
+			_, _ = svc.Extensions.Get(application.SecretsExtensionKey, &declared)
+
+			known := map[string]bool{}
+			for _, d := range declared {
+				known[d.Name] = true
+			}
+
+			seen := map[string]bool{}
+			for _, ref := range secretRefsIn(svc) {
+				if known[ref] || seen[ref] {
+					continue
+				}
+				seen[ref] = true
+				findings = append(findings, Finding{
+					Service: name,
+					Message: fmt.Sprintf("secret %q is referenced via ${secret:...} but not declared under x-incus-secrets", ref),
+				})
+			}
+		}
+		return findings
+	},
+}
+
+// secretRefsIn collects every ${secret:NAME} reference in svc's environment
+// values and volume sources.
+func secretRefsIn(svc dockercompose.ServiceConfig) []string {
+	var refs []string
+
+	for _, v := range svc.Environment {
+		if v == nil {
+			continue
+		}
+		for _, m := range secretRefPattern.FindAllStringSubmatch(*v, -1) {
+			refs = append(refs, m[1])
+		}
+	}
+
+	for _, vol := range svc.Volumes {
+		for _, m := range secretRefPattern.FindAllStringSubmatch(vol.Source, -1) {
+			refs = append(refs, m[1])
+		}
+	}
+
+	return refs
+}
+
+var ruleVolumePoolMismatch = Rule{
+	ID:          "R008",
+	Level:       LevelWarning,
+	Description: "a volume that sets x-incus-pool must not leave it empty; whether the named pool exists is not checked",
+	Check: func(c *application.Compose) []Finding {
+		var findings []Finding
+		for name, volume := range c.ComposeProject.Volumes {
+			if volume.External {
+				continue
+			}
+			var pool string
+			if ok, err := volume.Extensions.Get("x-incus-pool", &pool); !ok || err != nil {
+				continue
+			}
+			if pool == "" {
+				findings = append(findings, Finding{
+					Message: fmt.Sprintf("volume %q sets x-incus-pool but leaves it empty", name),
+				})
+			}
+		}
+		return findings
+	},
+}
@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"testing"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestSecretRefsInFindsEnvironmentAndVolumeReferences(t *testing.T) {
+	dbPassword := "${secret:db_password}"
+	plain := "not-a-secret"
+
+	svc := dockercompose.ServiceConfig{
+		Environment: dockercompose.MappingWithEquals{
+			"DB_PASSWORD": &dbPassword,
+			"OTHER":       &plain,
+		},
+		Volumes: []dockercompose.ServiceVolumeConfig{
+			{Source: "${secret:tls_cert}"},
+		},
+	}
+
+	refs := secretRefsIn(svc)
+	if len(refs) != 2 {
+		t.Fatalf("secretRefsIn() = %v, want 2 refs", refs)
+	}
+
+	found := map[string]bool{}
+	for _, r := range refs {
+		found[r] = true
+	}
+	if !found["db_password"] || !found["tls_cert"] {
+		t.Errorf("secretRefsIn() = %v, missing expected names", refs)
+	}
+}
+
+func TestSecretRefsInIgnoresPlainInterpolation(t *testing.T) {
+	plain := "${OTHER_VAR}"
+
+	svc := dockercompose.ServiceConfig{
+		Environment: dockercompose.MappingWithEquals{
+			"OTHER": &plain,
+		},
+	}
+
+	if refs := secretRefsIn(svc); len(refs) != 0 {
+		t.Errorf("secretRefsIn() = %v, want no matches for ordinary interpolation", refs)
+	}
+}
@@ -0,0 +1,213 @@
+package recipe
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Catalogue is a local checkout of the shared recipes git repository,
+// cached under `~/.cache/incus/recipes` so that repeated `up` runs don't
+// re-clone. Every recipe is a subdirectory of that one repo; versions are
+// tags of the form `<name>/<version>`.
+type Catalogue struct {
+	// CacheDir is the incus cache directory, e.g. `~/.cache/incus`.
+	CacheDir string
+	// URL is the git remote the catalogue is cloned from.
+	URL string
+}
+
+// NewCatalogue builds a Catalogue rooted at cacheDir, defaulting URL to
+// DefaultCatalogueURL when empty.
+func NewCatalogue(cacheDir, url string) *Catalogue {
+	if url == "" {
+		url = DefaultCatalogueURL
+	}
+	return &Catalogue{CacheDir: cacheDir, URL: url}
+}
+
+// repoDir is the single clone of the shared catalogue repo every recipe is
+// resolved out of.
+func (c *Catalogue) repoDir() string {
+	return filepath.Join(c.CacheDir, "recipes", "_catalogue")
+}
+
+// snapshotDir is where a resolved recipe's compose.yml/recipe.yml are
+// copied to, so the checked-out ref of the shared repo can keep moving on
+// without disturbing a recipe another service has already resolved in this
+// run.
+func (c *Catalogue) snapshotDir(ref Ref) string {
+	name := ref.Name
+	if ref.Version != "" {
+		name = fmt.Sprintf("%s@%s", ref.Name, ref.Version)
+	}
+	return filepath.Join(c.CacheDir, "recipes", name)
+}
+
+// tag is the git tag a versioned recipe reference resolves to in the shared
+// catalogue repo.
+func (c *Catalogue) tag(ref Ref) string {
+	return fmt.Sprintf("%s/%s", ref.Name, ref.Version)
+}
+
+// ensureRepo clones the shared catalogue repo on first use and fetches
+// updates otherwise.
+func (c *Catalogue) ensureRepo() error {
+	dir := c.repoDir()
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		slog.Info("cloning recipe catalogue", slog.String("url", c.URL))
+		if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+			return err
+		}
+		if err := c.run("", "clone", c.URL, dir); err != nil {
+			return fmt.Errorf("cloning recipe catalogue: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	slog.Debug("updating recipe catalogue")
+	if err := c.run(dir, "fetch", "--tags", "--force"); err != nil {
+		return fmt.Errorf("updating recipe catalogue: %w", err)
+	}
+	return nil
+}
+
+// Fetch ensures the shared catalogue is present locally, checks out the
+// requested recipe's version tag (or the default branch when no version
+// was requested), and copies that recipe's subdirectory out into its own
+// snapshot directory so it can be read independently of whatever ref the
+// shared clone checks out next.
+func (c *Catalogue) Fetch(ref Ref) (*Recipe, error) {
+	if err := c.ensureRepo(); err != nil {
+		return nil, err
+	}
+
+	repoDir := c.repoDir()
+
+	checkout := "origin/HEAD"
+	if ref.Version != "" {
+		checkout = c.tag(ref)
+	}
+	if err := c.run(repoDir, "checkout", checkout); err != nil {
+		return nil, fmt.Errorf("checking out %s@%s: %w", ref.Name, checkout, err)
+	}
+
+	recipeSrc := filepath.Join(repoDir, ref.Name)
+	if _, err := os.Stat(recipeSrc); err != nil {
+		return nil, fmt.Errorf("recipe %q not found in catalogue at %s: %w", ref.Name, checkout, err)
+	}
+
+	dst := c.snapshotDir(ref)
+	if err := copyDir(recipeSrc, dst); err != nil {
+		return nil, fmt.Errorf("snapshotting recipe %q: %w", ref.Name, err)
+	}
+
+	manifest, err := loadManifest(filepath.Join(dst, "recipe.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest for recipe %q: %w", ref.Name, err)
+	}
+
+	return &Recipe{
+		Ref:         ref,
+		Manifest:    manifest,
+		ComposeFile: filepath.Join(dst, "compose.yml"),
+	}, nil
+}
+
+// List returns the names of every recipe subdirectory in the shared
+// catalogue at its current checkout, fetching it first if necessary.
+func (c *Catalogue) List() ([]string, error) {
+	if err := c.ensureRepo(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(c.repoDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ".git" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(c.repoDir(), entry.Name(), "recipe.yml")); err != nil {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
+
+func (c *Catalogue) run(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w", string(out), err)
+	}
+	return nil
+}
+
+// copyDir recursively copies src into dst, replacing dst's previous
+// contents, so a recipe snapshot reflects exactly what the catalogue had
+// checked out at Fetch time.
+func copyDir(src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		if entry.IsDir() {
+			if err := copyDir(srcPath, dstPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := copyFile(srcPath, dstPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,140 @@
+package recipe
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// knownNetworkTypes mirrors the network types incus-compose knows how to
+// create; recipes declaring anything else are rejected at lint time rather
+// than failing later inside CreateNetworks.
+var knownNetworkTypes = map[string]bool{
+	"bridge":   true,
+	"ovn":      true,
+	"macvlan":  true,
+	"physical": true,
+}
+
+// knownExtensionKeys are every x-incus-* key something in incus-compose
+// actually reads; a recipe's compose.yml declaring anything else is almost
+// certainly a typo that will be silently ignored at merge/up time rather
+// than doing what the recipe author intended.
+var knownExtensionKeys = map[string]bool{
+	"x-incus-recipe":             true,
+	"x-incus-lint-ignore":        true,
+	"x-incus-image":              true,
+	"x-incus-pool":               true,
+	"x-incus-type":               true,
+	"x-incus-uplink":             true,
+	"x-incus-ipv4-address":       true,
+	"x-incus-ipv6-address":       true,
+	"x-incus-nat":                true,
+	"x-incus-dns-domain":         true,
+	"x-incus-devices":            true,
+	"x-incus-secrets":            true,
+	"x-incus-ovn-acls":           true,
+	"x-incus-ovn-forwards":       true,
+	"x-incus-ovn-peers":          true,
+	"x-incus-ovn-load-balancers": true,
+}
+
+// LintResult is a single problem found while validating a recipe.
+type LintResult struct {
+	Level   string // "error" or "warning"
+	Message string
+}
+
+// Lint validates a resolved recipe against the rules a catalogue entry must
+// satisfy: every env var it reads must be declared in RequiredEnv or
+// OptionalEnv, any network it asks for must resolve to a known type, and its
+// compose.yml only uses x-incus-* keys incus-compose understands.
+//
+// networkTypes is nil when the caller has no project to check the recipe's
+// declared networks against (e.g. the standalone `recipe lint` command,
+// which validates a catalogue entry in isolation) - that skips the network
+// check entirely rather than flagging every declared network as undefined.
+func Lint(rec *Recipe, usedEnv []string, networkTypes map[string]string) []LintResult {
+	var results []LintResult
+
+	declared := map[string]bool{}
+	for _, name := range rec.Manifest.RequiredEnv {
+		declared[name] = true
+	}
+	for name := range rec.Manifest.OptionalEnv {
+		declared[name] = true
+	}
+
+	for _, name := range usedEnv {
+		if !declared[name] {
+			results = append(results, LintResult{
+				Level:   "error",
+				Message: fmt.Sprintf("env var %q is used but not declared in required_env/optional_env", name),
+			})
+		}
+	}
+
+	if networkTypes != nil {
+		for _, netName := range rec.Manifest.Networks {
+			nettype, ok := networkTypes[netName]
+			if !ok {
+				results = append(results, LintResult{
+					Level:   "error",
+					Message: fmt.Sprintf("network %q is declared by the recipe but not defined by the project", netName),
+				})
+				continue
+			}
+			if !knownNetworkTypes[nettype] {
+				results = append(results, LintResult{
+					Level:   "error",
+					Message: fmt.Sprintf("network %q has unresolvable type %q", netName, nettype),
+				})
+			}
+		}
+	}
+
+	results = append(results, lintExtensionKeys(rec)...)
+
+	if rec.Manifest.Name == "" {
+		results = append(results, LintResult{Level: "warning", Message: "recipe.yml is missing a name"})
+	}
+	if rec.Manifest.Version == "" {
+		results = append(results, LintResult{Level: "warning", Message: "recipe.yml is missing a version"})
+	}
+
+	return results
+}
+
+// lintExtensionKeys flags any x-incus-* key in the recipe's compose.yml that
+// incus-compose doesn't actually read anywhere, since Merge would otherwise
+// silently drop it rather than erroring.
+func lintExtensionKeys(rec *Recipe) []LintResult {
+	var results []LintResult
+
+	bb, err := os.ReadFile(rec.ComposeFile)
+	if err != nil {
+		return results
+	}
+
+	var fragment dockercompose.ServiceConfig
+	if err := yaml.Unmarshal(bb, &fragment); err != nil {
+		return results
+	}
+
+	for key := range fragment.Extensions {
+		if !strings.HasPrefix(key, "x-incus-") {
+			continue
+		}
+		if !knownExtensionKeys[key] {
+			results = append(results, LintResult{
+				Level:   "warning",
+				Message: fmt.Sprintf("compose.yml declares unknown extension key %q", key),
+			})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,71 @@
+package recipe
+
+import (
+	"fmt"
+	"os"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+func loadManifest(path string) (Manifest, error) {
+	var m Manifest
+
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+
+	if err := yaml.Unmarshal(bb, &m); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// Merge loads a recipe's compose fragment and layers it under svc: fields
+// the user already set in the project explicitly win, everything the recipe
+// declares fills the gaps. overrides are environment values supplied by the
+// caller (project .env, CLI flags) and are validated against the recipe's
+// RequiredEnv before the merge is considered successful.
+func Merge(rec *Recipe, svc *dockercompose.ServiceConfig, overrides map[string]string) error {
+	for _, name := range rec.Manifest.RequiredEnv {
+		if _, ok := overrides[name]; !ok {
+			return fmt.Errorf("recipe %s requires env var %q to be set", rec.Ref, name)
+		}
+	}
+
+	bb, err := os.ReadFile(rec.ComposeFile)
+	if err != nil {
+		return err
+	}
+
+	var fragment dockercompose.ServiceConfig
+	if err := yaml.Unmarshal(bb, &fragment); err != nil {
+		return fmt.Errorf("parsing compose.yml for recipe %s: %w", rec.Ref, err)
+	}
+
+	if svc.Image == "" {
+		svc.Image = fragment.Image
+	}
+	if svc.Environment == nil {
+		svc.Environment = dockercompose.MappingWithEquals{}
+	}
+	for k, v := range fragment.Environment {
+		if _, ok := svc.Environment[k]; !ok {
+			svc.Environment[k] = v
+		}
+	}
+	for k, v := range overrides {
+		value := v
+		svc.Environment[k] = &value
+	}
+	if len(svc.Volumes) == 0 {
+		svc.Volumes = fragment.Volumes
+	}
+	if len(svc.Ports) == 0 {
+		svc.Ports = fragment.Ports
+	}
+
+	return nil
+}
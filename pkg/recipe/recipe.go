@@ -0,0 +1,64 @@
+// Package recipe implements the catalogue of reusable, parameterised compose
+// fragments that a project can pull in via the `x-incus-recipe` extension,
+// modelled after the recipe catalogue abra keeps for Coopcloud apps.
+package recipe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCatalogueURL is the git repository cloned into the cache directory
+// the first time a recipe is referenced, unless overridden by
+// INCUS_COMPOSE_RECIPE_CATALOGUE.
+const DefaultCatalogueURL = "https://github.com/gwenya/incus-compose-recipes.git"
+
+// Ref identifies a single recipe and the version pinned against it, as
+// written in compose files via `x-incus-recipe: nextcloud@1.2.0`.
+type Ref struct {
+	Name    string
+	Version string
+}
+
+// String renders the ref back into its `name@version` form.
+func (r Ref) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s@%s", r.Name, r.Version)
+}
+
+// ParseRef parses a `x-incus-recipe` value of the form `name` or
+// `name@version`. An empty version means "latest".
+func ParseRef(value string) (Ref, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Ref{}, fmt.Errorf("recipe reference is empty")
+	}
+
+	name, version, _ := strings.Cut(value, "@")
+	if name == "" {
+		return Ref{}, fmt.Errorf("recipe reference %q is missing a name", value)
+	}
+
+	return Ref{Name: name, Version: version}, nil
+}
+
+// Manifest is the metadata a recipe carries alongside its compose.yml,
+// declaring the inputs it expects from the consuming project.
+type Manifest struct {
+	Name        string            `yaml:"name"`
+	Version     string            `yaml:"version"`
+	Description string            `yaml:"description,omitempty"`
+	RequiredEnv []string          `yaml:"required_env,omitempty"`
+	OptionalEnv map[string]string `yaml:"optional_env,omitempty"`
+	Networks    []string          `yaml:"networks,omitempty"`
+}
+
+// Recipe is a resolved recipe: its manifest plus the path to the checked-out
+// compose fragment it was resolved from.
+type Recipe struct {
+	Ref         Ref
+	Manifest    Manifest
+	ComposeFile string
+}
@@ -0,0 +1,74 @@
+package recipe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestParseRef(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Ref
+		wantErr bool
+	}{
+		{"nextcloud@1.2.0", Ref{Name: "nextcloud", Version: "1.2.0"}, false},
+		{"nextcloud", Ref{Name: "nextcloud"}, false},
+		{"", Ref{}, true},
+		{"@1.2.0", Ref{}, true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseRef(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseRef(%q): expected error, got %+v", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRef(%q): unexpected error: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseRef(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRefString(t *testing.T) {
+	if got := (Ref{Name: "nextcloud", Version: "1.2.0"}).String(); got != "nextcloud@1.2.0" {
+		t.Errorf("String() = %q, want nextcloud@1.2.0", got)
+	}
+	if got := (Ref{Name: "nextcloud"}).String(); got != "nextcloud" {
+		t.Errorf("String() = %q, want nextcloud", got)
+	}
+}
+
+func TestMergeRequiresDeclaredEnv(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "compose.yml")
+	if err := os.WriteFile(composeFile, []byte("image: nextcloud:28\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &Recipe{
+		Ref:         Ref{Name: "nextcloud", Version: "1.2.0"},
+		Manifest:    Manifest{RequiredEnv: []string{"DB_PASSWORD"}},
+		ComposeFile: composeFile,
+	}
+
+	svc := dockercompose.ServiceConfig{}
+	if err := Merge(rec, &svc, map[string]string{}); err == nil {
+		t.Fatal("expected an error when a required env var is missing")
+	}
+
+	if err := Merge(rec, &svc, map[string]string{"DB_PASSWORD": "secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.Image != "nextcloud:28" {
+		t.Errorf("Image = %q, want nextcloud:28 (filled from recipe fragment)", svc.Image)
+	}
+}
@@ -0,0 +1,58 @@
+package recipe
+
+import (
+	"fmt"
+	"log/slog"
+
+	dockercompose "github.com/compose-spec/compose-go/v2/types"
+)
+
+// ExtensionKey is the compose extension a service uses to reference a
+// catalogue recipe, e.g. `x-incus-recipe: nextcloud@1.2.0`.
+const ExtensionKey = "x-incus-recipe"
+
+// ResolveProject walks every service in project looking for the
+// x-incus-recipe extension and, when found, fetches the referenced recipe
+// from cat and merges it into the service definition in place. It is called
+// from the root command after the compose file is loaded but before the
+// application graph is built, so recipes behave exactly like hand-written
+// services from that point on.
+func ResolveProject(project *dockercompose.Project, cat *Catalogue) error {
+	for name, svc := range project.Services {
+		var raw string
+		ok, err := svc.Extensions.Get(ExtensionKey, &raw)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		ref, err := ParseRef(raw)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+
+		slog.Info("resolving recipe", slog.String("service", name), slog.String("recipe", ref.String()))
+
+		rec, err := cat.Fetch(ref)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+
+		overrides := map[string]string{}
+		for k, v := range svc.Environment {
+			if v != nil {
+				overrides[k] = *v
+			}
+		}
+
+		if err := Merge(rec, &svc, overrides); err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+
+		project.Services[name] = svc
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Diff renders a line-per-change summary between two releases: images that
+// changed fingerprint, and networks whose config changed.
+func Diff(a, b *Release) string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "release %s -> %s\n", a.ShortSHA, b.ShortSHA)
+
+	for _, name := range sortedServiceKeys(a.Images, b.Images) {
+		oldImage, newImage := a.Images[name], b.Images[name]
+		if oldImage != newImage {
+			fmt.Fprintf(&out, "  image %s: %s -> %s\n", name, oldImage, newImage)
+		}
+	}
+
+	for _, name := range sortedNetworkKeys(a.Networks, b.Networks) {
+		oldNet, oldOK := a.Networks[name]
+		newNet, newOK := b.Networks[name]
+		if !oldOK {
+			fmt.Fprintf(&out, "  network %s: added\n", name)
+			continue
+		}
+		if !newOK {
+			fmt.Fprintf(&out, "  network %s: removed\n", name)
+			continue
+		}
+		if oldNet.Type != newNet.Type {
+			fmt.Fprintf(&out, "  network %s: type %s -> %s\n", name, oldNet.Type, newNet.Type)
+		}
+		for _, key := range sortedConfigKeys(oldNet.Config, newNet.Config) {
+			if oldNet.Config[key] != newNet.Config[key] {
+				fmt.Fprintf(&out, "  network %s: config %s: %q -> %q\n", name, key, oldNet.Config[key], newNet.Config[key])
+			}
+		}
+	}
+
+	return out.String()
+}
+
+func sortedServiceKeys(a, b map[string]string) []string {
+	set := map[string]bool{}
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedNetworkKeys(a, b map[string]Config) []string {
+	set := map[string]bool{}
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedConfigKeys(a, b map[string]string) []string {
+	set := map[string]bool{}
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+// Package release records every successful `up` as a versioned, replayable
+// snapshot, so a redeploy can pin exact image fingerprints and network
+// config instead of recomputing them and drifting from what was actually
+// last deployed.
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ConfigKey is the Incus project-level config key that points at the
+// currently active release's short SHA.
+const ConfigKey = "user.incus-compose.release"
+
+// dir is the project-relative path releases are written under.
+const dir = ".incus-compose/releases"
+
+// Release is everything needed to reproduce a deployed stack bit-for-bit.
+type Release struct {
+	Timestamp int64               `json:"timestamp"`
+	ShortSHA  string              `json:"short_sha"`
+	Compose   string              `json:"compose"`
+	Images    map[string]string   `json:"images"`   // service -> resolved image fingerprint
+	Profiles  map[string][]string `json:"profiles"` // service -> instance profiles at capture time
+	Networks  map[string]Config   `json:"networks"` // compose network key -> its api.NetworksPost config
+}
+
+// Config is the subset of a network's create/update payload that needs to
+// be pinned for a release to be bit-identical on replay.
+type Config struct {
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+}
+
+// path returns the on-disk location of a release given the project root dir.
+func path(projectDir string, rel *Release) string {
+	return filepath.Join(projectDir, dir, fmt.Sprintf("%d-%s.json", rel.Timestamp, rel.ShortSHA))
+}
+
+// Save writes rel under <projectDir>/.incus-compose/releases/.
+func Save(projectDir string, rel *Release) error {
+	if err := os.MkdirAll(filepath.Join(projectDir, dir), 0700); err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(rel, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path(projectDir, rel), bb, 0600)
+}
+
+// List returns every release recorded for projectDir, oldest first.
+func List(projectDir string) ([]*Release, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []*Release
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		bb, err := os.ReadFile(filepath.Join(projectDir, dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var rel Release
+		if err := json.Unmarshal(bb, &rel); err != nil {
+			return nil, fmt.Errorf("parsing release %s: %w", entry.Name(), err)
+		}
+		releases = append(releases, &rel)
+	}
+
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Timestamp < releases[j].Timestamp })
+	return releases, nil
+}
+
+// Find returns the release matching shortSHA, or an error if none does.
+func Find(projectDir, shortSHA string) (*Release, error) {
+	releases, err := List(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range releases {
+		if rel.ShortSHA == shortSHA {
+			return rel, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no release found with sha %q", shortSHA)
+}
+
+// Active returns the most recently recorded release, or nil if none exist
+// yet (a project's first `up`).
+func Active(projectDir string) (*Release, error) {
+	releases, err := List(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	return releases[len(releases)-1], nil
+}
@@ -0,0 +1,37 @@
+package release
+
+import (
+	"testing"
+)
+
+func TestSaveListFindRoundTripsProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	rel := &Release{
+		Timestamp: 1000,
+		ShortSHA:  "abc123",
+		Images:    map[string]string{"web": "img1"},
+		Profiles:  map[string][]string{"web": {"default", "web-profile"}},
+		Networks:  map[string]Config{"default": {Type: "bridge", Config: map[string]string{"ipv4.address": "auto"}}},
+	}
+
+	if err := Save(dir, rel); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	found, err := Find(dir, "abc123")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+
+	if len(found.Profiles["web"]) != 2 || found.Profiles["web"][1] != "web-profile" {
+		t.Errorf("Profiles not round-tripped: %+v", found.Profiles)
+	}
+}
+
+func TestFindReturnsErrorWhenMissing(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Find(dir, "nope"); err == nil {
+		t.Fatal("expected an error for an unknown release sha")
+	}
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// resolveAge decrypts an age-encrypted file using the identities found in
+// the file named by the INCUS_COMPOSE_AGE_IDENTITY env var, defaulting to
+// `~/.config/incus/age.key` to match where incus-compose keeps its own
+// config.
+func resolveAge(path string) (string, error) {
+	identityPath := os.Getenv("INCUS_COMPOSE_AGE_IDENTITY")
+	if identityPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		identityPath = home + "/.config/incus/age.key"
+	}
+
+	identityFile, err := os.Open(identityPath)
+	if err != nil {
+		return "", fmt.Errorf("opening age identity %s: %w", identityPath, err)
+	}
+	defer identityFile.Close()
+
+	identities, err := age.ParseIdentities(identityFile)
+	if err != nil {
+		return "", fmt.Errorf("parsing age identity %s: %w", identityPath, err)
+	}
+
+	encrypted, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening age secret %s: %w", path, err)
+	}
+	defer encrypted.Close()
+
+	r, err := age.Decrypt(encrypted, identities...)
+	if err != nil {
+		return "", fmt.Errorf("decrypting %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return "", fmt.Errorf("decrypting %s: %w", path, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,30 @@
+package secrets
+
+import "fmt"
+
+// generatedSecretBytes is the amount of random material `secret generate`
+// writes, encoded as hex before being stored.
+const generatedSecretBytes = 32
+
+// GenerateAndStore creates a new random secret and writes it back to
+// backend at path, returning the generated plaintext so callers can report
+// on it without reading it back from the store. Only the pass backend
+// supports writing today; age/sops secrets are encrypted against a
+// recipient key incus-compose has no business generating.
+func GenerateAndStore(backend, path string) (string, error) {
+	value, err := randomSecret(generatedSecretBytes)
+	if err != nil {
+		return "", err
+	}
+
+	switch backend {
+	case BackendPass, "":
+		if err := writePass(path, value); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("secret generate is not supported for backend %q", backend)
+	}
+
+	return value, nil
+}
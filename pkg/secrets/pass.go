@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// resolvePass shells out to `pass show <path>`, returning the first line of
+// its output (pass convention: the secret value is the first line, anything
+// after is metadata).
+func resolvePass(path string) (string, error) {
+	out, err := exec.Command("pass", "show", path).Output()
+	if err != nil {
+		return "", fmt.Errorf("pass show %s: %w", path, err)
+	}
+
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	return string(line), nil
+}
+
+// writePass inserts value into the pass store at path, overwriting any
+// existing entry, for use by `secret generate`/`secret rotate`.
+func writePass(path, value string) error {
+	cmd := exec.Command("pass", "insert", "-m", "-f", path)
+	cmd.Stdin = strings.NewReader(value + "\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pass insert %s: %s: %w", path, out, err)
+	}
+	return nil
+}
+
+// randomSecret generates a URL-safe random value suitable for `secret
+// generate`, sized in raw bytes before encoding.
+func randomSecret(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", buf), nil
+}
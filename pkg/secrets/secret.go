@@ -0,0 +1,85 @@
+// Package secrets resolves the `x-incus-secrets` compose extension at
+// deploy time against an external backend (pass, age or sops), so that
+// plaintext secret material never needs to live in the project directory.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Backend names accepted by --secrets-backend / INCUS_COMPOSE_SECRETS_BACKEND.
+const (
+	BackendPass = "pass"
+	BackendAge  = "age"
+	BackendSops = "sops"
+)
+
+// Spec is one entry under a service's `x-incus-secrets` list.
+type Spec struct {
+	// Name is how the secret is referred to in logs and `secret ls`.
+	Name string `yaml:"name"`
+	// Source is `pass:<path>`, `age:<file>`, `env:<VAR>` or `file:<path>`.
+	Source string `yaml:"source"`
+	// Target is the destination: an in-instance file path when it starts
+	// with "/" (see IsFile), otherwise an environment variable name.
+	Target string `yaml:"target"`
+	Mode   string `yaml:"mode,omitempty"`
+	UID    int    `yaml:"uid,omitempty"`
+	GID    int    `yaml:"gid,omitempty"`
+}
+
+// IsFile reports whether the secret should be pushed in as a file rather
+// than exposed as an environment config key.
+func (s Spec) IsFile() bool {
+	return strings.HasPrefix(s.Target, "/")
+}
+
+// Resolver resolves a Spec's Source into plaintext, using backend as the
+// default scheme when Source doesn't specify one (a bare secret name).
+type Resolver struct {
+	Backend string
+}
+
+// NewResolver builds a Resolver for the given backend name, defaulting to
+// BackendPass when empty.
+func NewResolver(backend string) *Resolver {
+	if backend == "" {
+		backend = BackendPass
+	}
+	return &Resolver{Backend: backend}
+}
+
+// Resolve returns the plaintext value for spec, dispatching on the scheme
+// prefix of its Source (`pass:`, `age:`, `env:`, `file:`), falling back to
+// the resolver's configured backend when Source has no scheme.
+func (r *Resolver) Resolve(spec Spec) (string, error) {
+	scheme, value, ok := strings.Cut(spec.Source, ":")
+	if !ok {
+		scheme, value = r.Backend, spec.Source
+	}
+
+	switch scheme {
+	case BackendPass:
+		return resolvePass(value)
+	case BackendAge:
+		return resolveAge(value)
+	case "env":
+		v, ok := os.LookupEnv(value)
+		if !ok {
+			return "", fmt.Errorf("secret %s: env var %q is not set", spec.Name, value)
+		}
+		return v, nil
+	case "file":
+		bb, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", spec.Name, err)
+		}
+		return strings.TrimRight(string(bb), "\n"), nil
+	case BackendSops:
+		return resolveSops(value)
+	default:
+		return "", fmt.Errorf("secret %s: unknown source scheme %q", spec.Name, scheme)
+	}
+}
@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsFile(t *testing.T) {
+	if !(Spec{Target: "/etc/app/secret"}).IsFile() {
+		t.Error("a target starting with / should be a file")
+	}
+	if (Spec{Target: "DB_PASSWORD"}).IsFile() {
+		t.Error("a bare env var name should not be a file")
+	}
+}
+
+func TestResolveEnvScheme(t *testing.T) {
+	t.Setenv("TEST_SECRET_VALUE", "hunter2")
+
+	r := NewResolver(BackendPass)
+	got, err := r.Resolve(Spec{Name: "db", Source: "env:TEST_SECRET_VALUE"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got != "hunter2" {
+		t.Errorf("Resolve() = %q, want hunter2", got)
+	}
+}
+
+func TestResolveEnvSchemeMissing(t *testing.T) {
+	r := NewResolver(BackendPass)
+	if _, err := r.Resolve(Spec{Name: "db", Source: "env:TEST_SECRET_DOES_NOT_EXIST"}); err == nil {
+		t.Fatal("expected an error for an unset env var")
+	}
+}
+
+func TestResolveFileScheme(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewResolver(BackendPass)
+	got, err := r.Resolve(Spec{Name: "db", Source: "file:" + path})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got != "s3cret" {
+		t.Errorf("Resolve() = %q, want s3cret (trailing newline trimmed)", got)
+	}
+}
+
+func TestResolveUnknownScheme(t *testing.T) {
+	r := NewResolver(BackendPass)
+	if _, err := r.Resolve(Spec{Name: "db", Source: "vault:foo"}); err == nil {
+		t.Fatal("expected an error for an unknown source scheme")
+	}
+}
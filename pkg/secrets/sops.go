@@ -0,0 +1,18 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// resolveSops decrypts value with `sops --decrypt` and returns its output
+// with trailing newlines trimmed (sops handles its own backend key resolution via its
+// usual .sops.yaml / env var configuration).
+func resolveSops(path string) (string, error) {
+	out, err := exec.Command("sops", "--decrypt", path).Output()
+	if err != nil {
+		return "", fmt.Errorf("sops --decrypt %s: %w", path, err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}